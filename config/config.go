@@ -0,0 +1,128 @@
+// Package config loads the YAML file that tells hashtag-bot which
+// repositories to watch, which Slack channels to post into, and how to
+// resolve a bare "#123" hashtag to one of potentially many repos.
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/babarot/hashtag-bot/provider"
+)
+
+// Backend names a supported Git-hosting provider.
+const (
+	BackendGitHub = "github"
+	BackendGitea  = "gitea"
+	BackendGitLab = "gitlab"
+)
+
+// Repo is one owner/repo target and the Slack channels it's wired to.
+type Repo struct {
+	Owner string `yaml:"owner"`
+	Name  string `yaml:"repo"`
+	// Backend selects the Git-hosting provider; defaults to "github".
+	Backend string `yaml:"backend"`
+	// BaseURL points at a self-hosted Gitea/GitLab instance. Unused for
+	// the "github" backend, which always talks to github.com.
+	BaseURL string `yaml:"base_url"`
+	// Token overrides GITHUB_ACCESS_TOKEN for this repo, so a single bot
+	// process can serve repos across multiple orgs/tokens/backends.
+	Token string `yaml:"token"`
+	// Channels lists the Slack channel IDs or names this repo is
+	// announced/looked up in.
+	Channels []string `yaml:"channels"`
+	// Prefix, when set, scopes hashtags to this repo within a channel
+	// shared by several repos, e.g. "#api-123" with Prefix "api". A
+	// plain "#123" still resolves to the channel's default repo (the
+	// one with no prefix, or the sole repo mapped to that channel).
+	Prefix string `yaml:"prefix"`
+	// WarmIssues lists issue/PR numbers to proactively fetch on the
+	// optional warm-up cron, so frequently-mentioned issues are already
+	// cached before anyone asks.
+	WarmIssues []int `yaml:"warm_issues"`
+}
+
+// Provider builds the provider.Provider for r's configured backend.
+func (r Repo) Provider() (provider.Provider, error) {
+	switch r.Backend {
+	case "", BackendGitHub:
+		token := r.Token
+		if token == "" {
+			token = os.Getenv("GITHUB_ACCESS_TOKEN")
+		}
+		return provider.NewGitHub(token), nil
+	case BackendGitea:
+		return provider.NewGitea(r.BaseURL, r.Token)
+	case BackendGitLab:
+		return provider.NewGitLab(r.BaseURL, r.Token)
+	default:
+		return nil, fmt.Errorf("config: unknown backend %q for %s", r.Backend, r.Key())
+	}
+}
+
+// Key is the "owner/repo" form used as the cache key prefix.
+func (r Repo) Key() string {
+	return fmt.Sprintf("%s/%s", r.Owner, r.Name)
+}
+
+// Config is the top-level YAML document.
+type Config struct {
+	Repos []Repo `yaml:"repos"`
+}
+
+// Load reads and parses a Config from path.
+func Load(path string) (Config, error) {
+	var cfg Config
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return cfg, err
+	}
+	return cfg, nil
+}
+
+// ReposForChannel returns every repo configured for channel.
+func (c Config) ReposForChannel(channel string) []Repo {
+	var out []Repo
+	for _, r := range c.Repos {
+		for _, ch := range r.Channels {
+			if ch == channel {
+				out = append(out, r)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// ResolveHashtag picks the repo a hashtag in channel refers to. When
+// prefix is empty it returns the channel's unprefixed default repo (the
+// one with no Prefix set), falling back to the sole repo mapped to the
+// channel if there's exactly one. When prefix is set it returns the repo
+// whose Prefix matches.
+func (c Config) ResolveHashtag(channel, prefix string) (Repo, bool) {
+	repos := c.ReposForChannel(channel)
+	if prefix != "" {
+		for _, r := range repos {
+			if r.Prefix == prefix {
+				return r, true
+			}
+		}
+		return Repo{}, false
+	}
+	for _, r := range repos {
+		if r.Prefix == "" {
+			return r, true
+		}
+	}
+	if len(repos) == 1 {
+		return repos[0], true
+	}
+	return Repo{}, false
+}
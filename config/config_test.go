@@ -0,0 +1,78 @@
+package config
+
+import "testing"
+
+func reposForChannelFixture() Config {
+	return Config{
+		Repos: []Repo{
+			{Owner: "babarot", Name: "hashtag-bot", Channels: []string{"C1"}},
+			{Owner: "babarot", Name: "api", Channels: []string{"C2"}, Prefix: "api"},
+			{Owner: "babarot", Name: "web", Channels: []string{"C2"}, Prefix: "web"},
+			{Owner: "babarot", Name: "solo", Channels: []string{"C3"}},
+		},
+	}
+}
+
+func TestReposForChannel(t *testing.T) {
+	cfg := reposForChannelFixture()
+
+	tests := []struct {
+		channel string
+		want    int
+	}{
+		{"C1", 1},
+		{"C2", 2},
+		{"C3", 1},
+		{"unknown", 0},
+	}
+	for _, tt := range tests {
+		if got := len(cfg.ReposForChannel(tt.channel)); got != tt.want {
+			t.Errorf("ReposForChannel(%q): got %d repos, want %d", tt.channel, got, tt.want)
+		}
+	}
+}
+
+func TestResolveHashtag(t *testing.T) {
+	cfg := reposForChannelFixture()
+
+	t.Run("prefix match", func(t *testing.T) {
+		r, ok := cfg.ResolveHashtag("C2", "api")
+		if !ok || r.Name != "api" {
+			t.Fatalf("ResolveHashtag(C2, api) = %+v, %v; want repo %q", r, ok, "api")
+		}
+	})
+
+	t.Run("unknown prefix", func(t *testing.T) {
+		if _, ok := cfg.ResolveHashtag("C2", "mobile"); ok {
+			t.Fatalf("ResolveHashtag(C2, mobile): got ok=true, want false")
+		}
+	})
+
+	t.Run("no prefix falls back to single repo in channel", func(t *testing.T) {
+		r, ok := cfg.ResolveHashtag("C3", "")
+		if !ok || r.Name != "solo" {
+			t.Fatalf("ResolveHashtag(C3, \"\") = %+v, %v; want repo %q", r, ok, "solo")
+		}
+	})
+
+	t.Run("no prefix with multiple repos and no default is ambiguous", func(t *testing.T) {
+		if _, ok := cfg.ResolveHashtag("C2", ""); ok {
+			t.Fatalf("ResolveHashtag(C2, \"\"): got ok=true, want false (two prefixed repos, no default)")
+		}
+	})
+
+	t.Run("no prefix with an explicit default repo", func(t *testing.T) {
+		cfg := reposForChannelFixture()
+		cfg.Repos = append(cfg.Repos, Repo{Owner: "babarot", Name: "default", Channels: []string{"C2"}})
+		r, ok := cfg.ResolveHashtag("C2", "")
+		if !ok || r.Name != "default" {
+			t.Fatalf("ResolveHashtag(C2, \"\") = %+v, %v; want the unprefixed repo %q", r, ok, "default")
+		}
+	})
+
+	t.Run("unknown channel", func(t *testing.T) {
+		if _, ok := cfg.ResolveHashtag("nope", ""); ok {
+			t.Fatalf("ResolveHashtag(nope, \"\"): got ok=true, want false")
+		}
+	})
+}
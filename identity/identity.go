@@ -0,0 +1,58 @@
+// Package identity stores the Slack user -> GitHub login mapping
+// established via the /link-github slash command, so that a click on one
+// of render's interactive buttons knows which GitHub account to act as.
+package identity
+
+import (
+	"io/ioutil"
+	"os"
+	"sync"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Store is a file-backed, mutex-guarded slack_user_id -> github_login
+// mapping.
+type Store struct {
+	path string
+
+	mu    sync.Mutex
+	links map[string]string
+}
+
+// Load reads the mapping from path, starting with an empty mapping if the
+// file doesn't exist yet (it's created on the first Set).
+func Load(path string) (*Store, error) {
+	s := &Store{path: path, links: map[string]string{}}
+	b, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(b, &s.links); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Lookup returns the GitHub login linked to slackUserID, if any.
+func (s *Store) Lookup(slackUserID string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	login, ok := s.links[slackUserID]
+	return login, ok
+}
+
+// Set links slackUserID to githubLogin and persists the mapping to disk.
+func (s *Store) Set(slackUserID, githubLogin string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.links[slackUserID] = githubLogin
+	b, err := yaml.Marshal(s.links)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(s.path, b, 0600)
+}
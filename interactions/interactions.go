@@ -0,0 +1,199 @@
+// Package interactions handles Slack Block Kit button clicks posted by
+// render's Close/Reopen/Assign-to-me/:+1: buttons, and the /link-github
+// slash command that establishes the Slack-user-to-GitHub-login mapping
+// those clicks are authorized against.
+package interactions
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/nlopes/slack"
+
+	"github.com/babarot/hashtag-bot/config"
+	"github.com/babarot/hashtag-bot/identity"
+	"github.com/babarot/hashtag-bot/provider"
+	"github.com/babarot/hashtag-bot/render"
+)
+
+// Server handles Slack interaction payloads and the /link-github slash
+// command for the repos in Config.
+type Server struct {
+	// SigningSecret is the Slack app's signing secret; requests are
+	// rejected unless they verify against it.
+	SigningSecret string
+	Config        config.Config
+	Identity      *identity.Store
+}
+
+// Handler returns the http.Handler for Slack's Interactivity request URL,
+// typically mounted at "/slack/interactions".
+func (s *Server) Handler() http.Handler {
+	return http.HandlerFunc(s.handleInteraction)
+}
+
+// LinkGitHubHandler returns the http.Handler for the /link-github slash
+// command.
+func (s *Server) LinkGitHubHandler() http.Handler {
+	return http.HandlerFunc(s.handleLinkGitHub)
+}
+
+func (s *Server) handleInteraction(w http.ResponseWriter, r *http.Request) {
+	_, ok := s.verified(w, r)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var cb slack.InteractionCallback
+	if err := json.Unmarshal([]byte(r.FormValue("payload")), &cb); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if len(cb.ActionCallback.BlockActions) == 0 {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	action := cb.ActionCallback.BlockActions[0]
+
+	owner, repoName, number, err := parseTarget(action.Value)
+	if err != nil {
+		log.Print("interactions: ", err)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	repo, ok := s.findRepo(owner, repoName)
+	if !ok {
+		log.Printf("interactions: unknown repo %s/%s", owner, repoName)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	login, ok := s.Identity.Lookup(cb.User.ID)
+	if !ok {
+		log.Printf("interactions: %s hasn't linked a GitHub login; run /link-github first", cb.User.ID)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	p, err := repo.Provider()
+	if err != nil {
+		log.Printf("interactions: %s/%s: %v", owner, repoName, err)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if err := s.apply(p, action.ActionID, owner, repoName, number, login); err != nil {
+		log.Printf("interactions: %s on %s/%s#%d: %v", action.ActionID, owner, repoName, number, err)
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// apply performs the mutation a button click maps to, against repo's own
+// provider so it lands on whichever backend (GitHub, Gitea, GitLab) the
+// repo is actually configured for. login is the GitHub/Gitea/GitLab
+// identity of the Slack user who clicked it.
+func (s *Server) apply(p provider.Provider, actionID, owner, repo string, number int, login string) error {
+	switch actionID {
+	case render.ActionClose:
+		return p.SetIssueState(owner, repo, number, "closed")
+	case render.ActionReopen:
+		return p.SetIssueState(owner, repo, number, "open")
+	case render.ActionAssignMe:
+		return p.AddAssignees(owner, repo, number, []string{login})
+	case render.ActionThumbsUp:
+		return p.AddReaction(owner, repo, number, "+1")
+	default:
+		return fmt.Errorf("interactions: unknown action %q", actionID)
+	}
+}
+
+func (s *Server) handleLinkGitHub(w http.ResponseWriter, r *http.Request) {
+	_, ok := s.verified(w, r)
+	if !ok {
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID := r.FormValue("user_id")
+	login := strings.TrimSpace(r.FormValue("text"))
+	if userID == "" || login == "" {
+		respondEphemeral(w, "Usage: /link-github <your-github-login>")
+		return
+	}
+	if err := s.Identity.Set(userID, login); err != nil {
+		log.Print("interactions: ", err)
+		respondEphemeral(w, "Sorry, couldn't save that link.")
+		return
+	}
+	respondEphemeral(w, fmt.Sprintf("Linked <@%s> to GitHub user %s.", userID, login))
+}
+
+// verified reads and returns the request body after checking its Slack
+// signature, writing an error response and returning ok=false if it
+// doesn't verify.
+func (s *Server) verified(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, false
+	}
+	r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	verifier, err := slack.NewSecretsVerifier(r.Header, s.SigningSecret)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return nil, false
+	}
+	if _, err := verifier.Write(body); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, false
+	}
+	if err := verifier.Ensure(); err != nil {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return nil, false
+	}
+	return body, true
+}
+
+func (s *Server) findRepo(owner, name string) (config.Repo, bool) {
+	for _, r := range s.Config.Repos {
+		if r.Owner == owner && r.Name == name {
+			return r, true
+		}
+	}
+	return config.Repo{}, false
+}
+
+// parseTarget splits a button value of the form "owner/repo/number" built
+// by render.Blocks.
+func parseTarget(value string) (owner, repo string, number int, err error) {
+	parts := strings.SplitN(value, "/", 3)
+	if len(parts) != 3 {
+		return "", "", 0, fmt.Errorf("malformed button value %q", value)
+	}
+	number, err = strconv.Atoi(parts[2])
+	if err != nil {
+		return "", "", 0, fmt.Errorf("malformed button value %q: %v", value, err)
+	}
+	return parts[0], parts[1], number, nil
+}
+
+func respondEphemeral(w http.ResponseWriter, text string) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"response_type": "ephemeral",
+		"text":          text,
+	})
+}
@@ -0,0 +1,27 @@
+package interactions
+
+import "testing"
+
+func TestParseTarget(t *testing.T) {
+	owner, repo, number, err := parseTarget("babarot/hashtag-bot/42")
+	if err != nil {
+		t.Fatalf("parseTarget: unexpected error: %v", err)
+	}
+	if owner != "babarot" || repo != "hashtag-bot" || number != 42 {
+		t.Errorf("parseTarget: got (%q, %q, %d), want (%q, %q, %d)", owner, repo, number, "babarot", "hashtag-bot", 42)
+	}
+}
+
+func TestParseTargetMalformed(t *testing.T) {
+	tests := []string{
+		"",
+		"babarot",
+		"babarot/hashtag-bot",
+		"babarot/hashtag-bot/notanumber",
+	}
+	for _, value := range tests {
+		if _, _, _, err := parseTarget(value); err == nil {
+			t.Errorf("parseTarget(%q): got nil error, want an error", value)
+		}
+	}
+}
@@ -1,67 +1,144 @@
 package main
 
 import (
-	"encoding/json"
-	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/google/go-github/github"
 	"github.com/nlopes/slack"
-	"github.com/patrickmn/go-cache"
 	"github.com/robfig/cron"
 	"golang.org/x/oauth2"
-)
 
-const (
-	STATE_OPEN      = "#67C63D"
-	STATE_CLOSED    = "#B52003"
-	STATE_MERGED    = "#65488D"
-	STATE_NOT_FOUND = "#D3D3D3"
+	"github.com/babarot/hashtag-bot/config"
+	"github.com/babarot/hashtag-bot/identity"
+	"github.com/babarot/hashtag-bot/interactions"
+	"github.com/babarot/hashtag-bot/metrics"
+	"github.com/babarot/hashtag-bot/provider"
+	"github.com/babarot/hashtag-bot/render"
+	"github.com/babarot/hashtag-bot/store"
+	"github.com/babarot/hashtag-bot/tasks"
+	"github.com/babarot/hashtag-bot/webhook"
 )
 
-var c *cache.Cache = cache.New(60*time.Minute, 30*time.Second)
-var pattern *regexp.Regexp = regexp.MustCompile("#([0-9]+)")
+// issueTTL is how long a positively-resolved hashtag lookup stays
+// cached before the next mention re-fetches it from the provider.
+const issueTTL = 5 * time.Minute
+
+var c *store.Cache
+
+// pattern matches "#123" or a prefixed "#api-123"; group 1 is the
+// optional prefix, group 2 is the issue/PR number.
+var pattern *regexp.Regexp = regexp.MustCompile(`#(?:([a-zA-Z0-9_-]+)-)?([0-9]+)`)
 
 var (
-	repo = flag.String("repo", "", "Specify github.com repository name")
-	user = flag.String("user", "", "Specify github.com user name")
+	configPath  = flag.String("config", "", "Path to the YAML file listing repos, their Slack channels and hashtag prefixes")
+	cacheSize   = flag.Int("cache-size", 1000, "Maximum number of issues/PRs to keep in the LRU hashtag-lookup cache")
+	tasksConfig = flag.String("tasks-config", "", "Path to a YAML file enabling maintenance tasks (see tasks package)")
+	dryRun      = flag.Bool("dry-run", false, "Log intended task mutations instead of calling each repo's provider API")
+	warmup      = flag.Bool("warmup", false, "Run an hourly cron that pre-fetches each repo's warm_issues into the cache")
+
+	webhookAddr    = flag.String("webhook-addr", "", "If set, listen on this address for GitHub webhook deliveries and /metrics (e.g. :8081)")
+	webhookSecret  = flag.String("webhook-secret", "", "Shared secret configured on the GitHub webhook, validated against X-Hub-Signature-256")
+	announceEvents = flag.String("announce-events", "", "Comma-separated webhook event names to post to Slack (issues,pull_request,issue_comment,pull_request_review)")
+
+	slackSigningSecret = flag.String("slack-signing-secret", "", "Slack app signing secret, used to verify /slack/interactions and /slack/link-github requests")
+	identityPath       = flag.String("identity-file", "", "Path to the YAML file mapping Slack user IDs to GitHub logins, maintained via /link-github")
 )
 
 func main() {
 	flag.Parse()
+	if *configPath == "" {
+		log.Print("-config is required")
+		os.Exit(1)
+	}
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Print(err)
+		os.Exit(1)
+	}
+	c, err = store.New(*cacheSize)
+	if err != nil {
+		log.Print(err)
+		os.Exit(1)
+	}
 	api := slack.New(os.Getenv("SLACK_TOKEN"))
-	os.Exit(run(api))
+	os.Exit(run(api, cfg))
 }
 
-func run(api *slack.Client) int {
+func run(api *slack.Client, cfg config.Config) int {
 	rtm := api.NewRTM()
 	go rtm.ManageConnection()
 
-	if c.ItemCount() == 0 {
-		resp, err := fetchIssuesFromGitHub(*user, *repo)
+	var tasksCfg tasks.Config
+	if *tasksConfig != "" {
+		var err error
+		tasksCfg, err = tasks.LoadConfig(*tasksConfig)
 		if err != nil {
 			log.Print(err)
 			return 1
 		}
-		log.Print(resp)
 	}
+	tasksCfg.DryRun = *dryRun
 
 	cr := cron.New()
-	cr.AddFunc("@hourly", func() {
-		resp, err := fetchIssuesFromGitHub(*user, *repo)
-		if err != nil {
-			log.Print(err)
-		}
-		log.Print("cron: ", resp)
-	})
+	if *warmup {
+		cr.AddFunc("@hourly", func() {
+			warmCache(cfg)
+		})
+	}
+	if *tasksConfig != "" {
+		cr.AddFunc("@hourly", func() {
+			runTasks(tasksCfg, cfg, api)
+		})
+	}
 	cr.Start()
 
+	if *webhookAddr != "" {
+		srv := &webhook.Server{
+			Secret:         *webhookSecret,
+			Config:         cfg,
+			Cache:          c,
+			Slack:          api,
+			AnnounceEvents: splitCSV(*announceEvents),
+		}
+		mux := http.NewServeMux()
+		mux.Handle("/webhook", srv.Handler())
+		mux.Handle("/metrics", metrics.Handler())
+
+		if *slackSigningSecret != "" {
+			if *identityPath == "" {
+				log.Print("-identity-file is required when -slack-signing-secret is set")
+				return 1
+			}
+			idStore, err := identity.Load(*identityPath)
+			if err != nil {
+				log.Print(err)
+				return 1
+			}
+			isrv := &interactions.Server{
+				SigningSecret: *slackSigningSecret,
+				Config:        cfg,
+				Identity:      idStore,
+			}
+			mux.Handle("/slack/interactions", isrv.Handler())
+			mux.Handle("/slack/link-github", isrv.LinkGitHubHandler())
+		}
+
+		go func() {
+			log.Printf("webhook: listening on %s", *webhookAddr)
+			if err := http.ListenAndServe(*webhookAddr, mux); err != nil {
+				log.Print("webhook: ", err)
+			}
+		}()
+	}
+
 	for {
 		select {
 		case msg := <-rtm.IncomingEvents:
@@ -71,13 +148,21 @@ func run(api *slack.Client) int {
 
 			case *slack.MessageEvent:
 				pat := pattern.FindStringSubmatch(ev.Text)
-				if len(pat) > 1 {
-					params := getPostMessageParameters(strings.TrimPrefix(pat[1], "#"))
-					_, _, err := api.PostMessage(ev.Channel, "", params)
-					if err != nil {
-						log.Print(err)
-						return 1
-					}
+				if len(pat) == 0 {
+					continue
+				}
+				repo, ok := cfg.ResolveHashtag(ev.Channel, pat[1])
+				if !ok {
+					continue
+				}
+				opts, ok := getPostMessageOptions(repo, pat[2])
+				if !ok {
+					continue
+				}
+				_, _, err := api.PostMessage(ev.Channel, opts...)
+				if err != nil {
+					log.Print(err)
+					return 1
 				}
 
 			case *slack.InvalidAuthEvent:
@@ -88,83 +173,218 @@ func run(api *slack.Client) int {
 	}
 }
 
-func getPostMessageParameters(n string) slack.PostMessageParameters {
-	key, found := c.Get(n)
-	if !found {
-		log.Printf("%s: no such item, fetch all issues again...\n", n)
-		fetchIssuesFromGitHub(*user, *repo)
+// getPostMessageOptions resolves a single hashtag number against repo,
+// checking the cache first and falling back to a single-issue fetch from
+// repo's provider on a miss. 404s are negatively cached so repeated
+// mentions of a nonexistent number don't keep hitting the provider. The
+// returned bool is false when there's nothing worth posting (bad number,
+// cached 404, fetch error).
+func getPostMessageOptions(repo config.Repo, n string) ([]slack.MsgOption, bool) {
+	number, err := strconv.Atoi(n)
+	if err != nil {
+		return nil, false
 	}
-	if key == nil {
-		return slack.PostMessageParameters{}
+	key := fmt.Sprintf("%s/%d", repo.Key(), number)
+
+	if issue, found, cached := c.Get(key); cached {
+		if !found {
+			return nil, false
+		}
+		return render.BlockOptions(repo.Owner, repo.Name, issue, prContext(repo, issue)), true
 	}
 
-	issue := key.(github.Issue)
+	p, err := repo.Provider()
+	if err != nil {
+		log.Print(err)
+		return nil, false
+	}
 
-	color := STATE_NOT_FOUND
-	switch *issue.State {
-	case "open":
-		color = STATE_OPEN
-	case "closed":
-		color = STATE_CLOSED
-		if issue.PullRequestLinks != nil {
-			color = STATE_MERGED
+	issue, err := p.GetIssue(repo.Owner, repo.Name, number)
+	recordRateLimit(repo, p)
+	if err != nil {
+		if provider.IsNotFound(err) {
+			c.SetNotFound(key)
+		} else {
+			// A transient failure (timeout, 5xx, bad token) isn't the
+			// same as "no such issue" — don't negative-cache it, or a
+			// real outage looks like a string of nonexistent numbers.
+			log.Printf("%s: %v", key, err)
 		}
+		return nil, false
 	}
+	c.Set(key, issue, issueTTL)
+	return render.BlockOptions(repo.Owner, repo.Name, issue, prContext(repo, issue)), true
+}
 
-	method := "Pull Requests"
-	if issue.PullRequestLinks == nil {
-		method = "Issues"
+// prContext fetches the GitHub-specific CI status, review decision and
+// mergeable state for issue, when it's a pull request on a GitHub-backed
+// repo. Other backends and plain issues get no extra context blocks.
+func prContext(repo config.Repo, issue provider.Issue) *render.PRContext {
+	if !issue.IsPR || (repo.Backend != "" && repo.Backend != config.BackendGitHub) {
+		return nil
 	}
+	gh := newGitHubClient(repo.Token)
 
-	params := slack.PostMessageParameters{
-		Markdown:  true,
-		Username:  "hashtag-bot",
-		IconEmoji: ":hash:",
+	var ctx render.PRContext
+	pr, _, err := gh.PullRequests.Get(repo.Owner, repo.Name, issue.Number)
+	if err != nil {
+		log.Printf("prContext: %s/%s#%d: %v", repo.Owner, repo.Name, issue.Number, err)
+		return &ctx
 	}
-	params.Attachments = []slack.Attachment{}
-	params.Attachments = append(params.Attachments, slack.Attachment{
-		Fallback:   fmt.Sprintf("%d - %s", *issue.Number, *issue.Title),
-		Title:      fmt.Sprintf("<%s|%s>", *issue.HTMLURL, *issue.Title),
-		Text:       *issue.Body,
-		MarkdownIn: []string{"title", "text", "fields", "fallback"},
-		Color:      color,
-		ThumbURL:   *issue.User.AvatarURL,
-		Footer:     "GitHub " + method,
-		Ts:         json.Number(fmt.Sprint((*issue.CreatedAt).Unix())),
-	})
-	return params
+
+	switch {
+	case pr.Mergeable == nil:
+		ctx.Mergeable = "unknown"
+	case *pr.Mergeable:
+		ctx.Mergeable = "mergeable"
+	default:
+		ctx.Mergeable = "conflicting"
+	}
+
+	if pr.Head != nil && pr.Head.SHA != nil {
+		if status, _, err := gh.Repositories.GetCombinedStatus(repo.Owner, repo.Name, *pr.Head.SHA, nil); err == nil && status.State != nil {
+			ctx.CIStatus = *status.State
+		}
+	}
+	if reviews, err := listReviews(gh, repo.Owner, repo.Name, issue.Number); err == nil {
+		ctx.ReviewDecision = reviewDecision(reviews)
+	}
+	return &ctx
+}
+
+// prReview is the subset of a pull request review listReviews needs.
+type prReview struct {
+	State string `json:"state"`
+	User  struct {
+		Login string `json:"login"`
+	} `json:"user"`
 }
 
-func fetchIssuesFromGitHub(user, repo string) (string, error) {
-	if user == "" || repo == "" {
-		return "", errors.New("user/repo invalid format")
+// listReviews fetches a pull request's reviews. There's no
+// PullRequests.ListReviews on this go-github version, so this hits the
+// REST endpoint directly the same way the generated methods do.
+func listReviews(gh *github.Client, owner, repo string, number int) ([]prReview, error) {
+	u := fmt.Sprintf("repos/%s/%s/pulls/%d/reviews", owner, repo, number)
+	req, err := gh.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
 	}
+	var reviews []prReview
+	if _, err := gh.Do(req, &reviews); err != nil {
+		return nil, err
+	}
+	return reviews, nil
+}
 
-	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: os.Getenv("GITHUB_ACCESS_TOKEN")},
-	)
-	tc := oauth2.NewClient(oauth2.NoContext, ts)
-	githubClient := github.NewClient(tc)
+// reviewDecision collapses a PR's reviews down to its latest state per
+// reviewer: any outstanding change request wins, otherwise any approval,
+// otherwise review is still needed.
+func reviewDecision(reviews []prReview) string {
+	latest := map[string]string{}
+	for _, r := range reviews {
+		if r.User.Login == "" || r.State == "" {
+			continue
+		}
+		latest[r.User.Login] = r.State
+	}
+	approved := false
+	for _, state := range latest {
+		switch state {
+		case "CHANGES_REQUESTED":
+			return "changes_requested"
+		case "APPROVED":
+			approved = true
+		}
+	}
+	if approved {
+		return "approved"
+	}
+	return "review_required"
+}
 
-	opt := &github.IssueListByRepoOptions{
-		State:       "all",
-		ListOptions: github.ListOptions{PerPage: 100},
+// warmCache proactively fetches each repo's configured warm_issues, so
+// frequently-mentioned issues are already cached before anyone asks.
+func warmCache(cfg config.Config) {
+	for _, r := range cfg.Repos {
+		if len(r.WarmIssues) == 0 {
+			continue
+		}
+		p, err := r.Provider()
+		if err != nil {
+			log.Print(err)
+			continue
+		}
+		for _, number := range r.WarmIssues {
+			issue, err := p.GetIssue(r.Owner, r.Name, number)
+			recordRateLimit(r, p)
+			if err != nil {
+				log.Printf("warmup: %s#%d: %v", r.Key(), number, err)
+				continue
+			}
+			c.Set(fmt.Sprintf("%s/%d", r.Key(), number), issue, issueTTL)
+		}
 	}
+}
 
-	n := 0
-	for {
-		repos, resp, err := githubClient.Issues.ListByRepo(user, repo, opt)
+// runTasks lists each configured repo fresh (tasks need the full repo,
+// not just whatever's in the lazy hashtag cache) and runs the enabled
+// maintenance tasks against it.
+func runTasks(tasksCfg tasks.Config, cfg config.Config, api *slack.Client) {
+	for _, r := range cfg.Repos {
+		p, err := r.Provider()
 		if err != nil {
-			return "", err
+			log.Print("tasks: ", err)
+			continue
 		}
-		for _, v := range repos {
-			c.Set(fmt.Sprintf("%d", *v.Number), *v, cache.DefaultExpiration)
-			n++
+		issues, err := p.ListIssues(r.Owner, r.Name)
+		if err != nil {
+			log.Print("tasks: ", err)
+			continue
 		}
-		if resp.NextPage == 0 {
-			break
+		if err := tasks.RunAll(tasksCfg, p, api, issues, r.Owner, r.Name); err != nil {
+			log.Print("tasks: ", err)
+		}
+	}
+}
+
+// recordRateLimit publishes a GitHub provider's most recently observed
+// rate-limit state to Prometheus, so operators can see remaining quota
+// via /metrics. Other backends don't expose this and are skipped.
+func recordRateLimit(r config.Repo, p provider.Provider) {
+	rl, ok := p.(interface {
+		RateLimit() (remaining, limit int, reset time.Time)
+	})
+	if !ok {
+		return
+	}
+	remaining, limit, reset := rl.RateLimit()
+	metrics.RateLimitRemaining.WithLabelValues(r.Owner, r.Name).Set(float64(remaining))
+	metrics.RateLimitLimit.WithLabelValues(r.Owner, r.Name).Set(float64(limit))
+	metrics.RateLimitResetSeconds.WithLabelValues(r.Owner, r.Name).Set(float64(reset.Unix()))
+}
+
+func splitCSV(s string) []string {
+	if s == "" {
+		return nil
+	}
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
 		}
-		opt.ListOptions.Page = resp.NextPage
 	}
-	return fmt.Sprintf("%d repos fetched in cache", n), nil
+	return out
+}
+
+// newGitHubClient builds a client authenticated with token, falling back
+// to GITHUB_ACCESS_TOKEN when token is empty so repos without their own
+// token still work. Used for fetching GitHub-specific PR context (CI
+// status, review decisions) that provider.Provider doesn't expose.
+func newGitHubClient(token string) *github.Client {
+	if token == "" {
+		token = os.Getenv("GITHUB_ACCESS_TOKEN")
+	}
+	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
+	tc := oauth2.NewClient(oauth2.NoContext, ts)
+	return github.NewClient(tc)
 }
@@ -0,0 +1,37 @@
+// Package metrics exposes hashtag-bot's operational gauges, currently
+// the GitHub API rate-limit headroom per repo, on a Prometheus /metrics
+// endpoint.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+var (
+	RateLimitRemaining = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "hashtag_bot_rate_limit_remaining",
+		Help: "Remaining API calls in the current rate-limit window, per repo.",
+	}, []string{"owner", "repo"})
+
+	RateLimitLimit = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "hashtag_bot_rate_limit_limit",
+		Help: "Total API rate limit for the current window, per repo.",
+	}, []string{"owner", "repo"})
+
+	RateLimitResetSeconds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "hashtag_bot_rate_limit_reset_seconds",
+		Help: "Unix time the current rate-limit window resets, per repo.",
+	}, []string{"owner", "repo"})
+)
+
+func init() {
+	prometheus.MustRegister(RateLimitRemaining, RateLimitLimit, RateLimitResetSeconds)
+}
+
+// Handler returns the /metrics HTTP handler.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
@@ -0,0 +1,172 @@
+package provider
+
+import (
+	"net/http"
+
+	"code.gitea.io/sdk/gitea"
+)
+
+// Gitea wraps the Gitea SDK client as a Provider, for self-hosted Gitea
+// instances.
+type Gitea struct {
+	client *gitea.Client
+}
+
+// NewGitea builds a Gitea provider against baseURL, authenticated with
+// token.
+func NewGitea(baseURL, token string) (*Gitea, error) {
+	client, err := gitea.NewClient(baseURL, gitea.SetToken(token))
+	if err != nil {
+		return nil, err
+	}
+	return &Gitea{client: client}, nil
+}
+
+func (p *Gitea) ListIssues(owner, repo string) ([]Issue, error) {
+	var out []Issue
+	page := 1
+	for {
+		issues, _, err := p.client.ListRepoIssues(owner, repo, gitea.ListIssueOption{
+			ListOptions: gitea.ListOptions{Page: page, PageSize: 50},
+			State:       gitea.StateAll,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(issues) == 0 {
+			break
+		}
+		for _, v := range issues {
+			out = append(out, normalizeGiteaIssue(v))
+		}
+		page++
+	}
+	return out, nil
+}
+
+func (p *Gitea) GetIssue(owner, repo string, number int) (Issue, error) {
+	issue, resp, err := p.client.GetIssue(owner, repo, int64(number))
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return Issue{}, &NotFoundError{Owner: owner, Repo: repo, Number: number}
+		}
+		return Issue{}, err
+	}
+	return normalizeGiteaIssue(issue), nil
+}
+
+func (p *Gitea) SetIssueState(owner, repo string, number int, state string) error {
+	s := gitea.StateType(state)
+	_, _, err := p.client.EditIssue(owner, repo, int64(number), gitea.EditIssueOption{State: &s})
+	return err
+}
+
+func (p *Gitea) AddLabels(owner, repo string, number int, labels []string) error {
+	ids, err := p.labelIDs(owner, repo, labels)
+	if err != nil {
+		return err
+	}
+	_, _, err = p.client.AddIssueLabels(owner, repo, int64(number), gitea.IssueLabelsOption{Labels: ids})
+	return err
+}
+
+// labelIDs resolves label names to the numeric IDs Gitea's label API
+// takes, since AddIssueLabels doesn't accept names directly.
+func (p *Gitea) labelIDs(owner, repo string, names []string) ([]int64, error) {
+	all, _, err := p.client.ListRepoLabels(owner, repo, gitea.ListLabelsOptions{})
+	if err != nil {
+		return nil, err
+	}
+	var ids []int64
+	for _, want := range names {
+		for _, l := range all {
+			if l.Name == want {
+				ids = append(ids, l.ID)
+			}
+		}
+	}
+	return ids, nil
+}
+
+// RequestReviewers asks the given logins to review a Gitea pull request.
+func (p *Gitea) RequestReviewers(owner, repo string, number int, reviewers []string) error {
+	_, err := p.client.CreateReviewRequests(owner, repo, int64(number), gitea.PullReviewRequestOptions{
+		Reviewers: reviewers,
+	})
+	return err
+}
+
+func (p *Gitea) AddAssignees(owner, repo string, number int, logins []string) error {
+	assignees, err := p.withAssignees(owner, repo, number, logins, true)
+	if err != nil {
+		return err
+	}
+	_, _, err = p.client.EditIssue(owner, repo, int64(number), gitea.EditIssueOption{Assignees: assignees})
+	return err
+}
+
+func (p *Gitea) RemoveAssignees(owner, repo string, number int, logins []string) error {
+	assignees, err := p.withAssignees(owner, repo, number, logins, false)
+	if err != nil {
+		return err
+	}
+	_, _, err = p.client.EditIssue(owner, repo, int64(number), gitea.EditIssueOption{Assignees: assignees})
+	return err
+}
+
+// withAssignees fetches number's current assignees and either adds or
+// removes logins from that set, since EditIssue replaces the whole
+// assignee list rather than patching it.
+func (p *Gitea) withAssignees(owner, repo string, number int, logins []string, add bool) ([]string, error) {
+	issue, _, err := p.client.GetIssue(owner, repo, int64(number))
+	if err != nil {
+		return nil, err
+	}
+	current := map[string]bool{}
+	for _, a := range issue.Assignees {
+		current[a.UserName] = true
+	}
+	for _, l := range logins {
+		if add {
+			current[l] = true
+		} else {
+			delete(current, l)
+		}
+	}
+	out := make([]string, 0, len(current))
+	for login := range current {
+		out = append(out, login)
+	}
+	return out, nil
+}
+
+func (p *Gitea) AddReaction(owner, repo string, number int, reaction string) error {
+	_, _, err := p.client.PostIssueReaction(owner, repo, int64(number), reaction)
+	return err
+}
+
+func normalizeGiteaIssue(issue *gitea.Issue) Issue {
+	out := Issue{
+		Number:  int(issue.Index),
+		Title:   issue.Title,
+		Body:    issue.Body,
+		State:   string(issue.State),
+		HTMLURL: issue.HTMLURL,
+		IsPR:    issue.PullRequest != nil,
+	}
+	if issue.Poster != nil {
+		out.AvatarURL = issue.Poster.AvatarURL
+	}
+	if issue.PullRequest != nil {
+		out.Merged = issue.PullRequest.Merged != nil
+	}
+	out.CreatedAt = issue.Created
+	out.UpdatedAt = issue.Updated
+	for _, l := range issue.Labels {
+		out.Labels = append(out.Labels, l.Name)
+	}
+	for _, a := range issue.Assignees {
+		out.Assignees = append(out.Assignees, a.UserName)
+	}
+	return out
+}
@@ -0,0 +1,232 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/github"
+	"golang.org/x/oauth2"
+)
+
+// GitHub wraps a go-github client as a Provider.
+type GitHub struct {
+	client *github.Client
+
+	mu   sync.Mutex
+	rate github.Rate
+}
+
+// NewGitHub builds a GitHub provider authenticated with token.
+func NewGitHub(token string) *GitHub {
+	ts := oauth2.StaticTokenSource(&oauth2.Token{AccessToken: token})
+	tc := oauth2.NewClient(oauth2.NoContext, ts)
+	return &GitHub{client: github.NewClient(tc)}
+}
+
+func (p *GitHub) ListIssues(owner, repo string) ([]Issue, error) {
+	opt := &github.IssueListByRepoOptions{
+		State:       "all",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	var out []Issue
+	for {
+		issues, resp, err := p.client.Issues.ListByRepo(owner, repo, opt)
+		if err != nil {
+			return nil, err
+		}
+		p.recordRate(resp)
+		for _, v := range issues {
+			out = append(out, normalizeGitHubIssue(v))
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opt.ListOptions.Page = resp.NextPage
+	}
+	return out, nil
+}
+
+func (p *GitHub) GetIssue(owner, repo string, number int) (Issue, error) {
+	issue, resp, err := p.client.Issues.Get(owner, repo, number)
+	if resp != nil {
+		p.recordRate(resp)
+	}
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			return Issue{}, &NotFoundError{Owner: owner, Repo: repo, Number: number}
+		}
+		return Issue{}, err
+	}
+	return normalizeGitHubIssue(issue), nil
+}
+
+func (p *GitHub) SetIssueState(owner, repo string, number int, state string) error {
+	_, resp, err := p.client.Issues.Edit(owner, repo, number, &github.IssueRequest{State: &state})
+	if resp != nil {
+		p.recordRate(resp)
+	}
+	return err
+}
+
+func (p *GitHub) AddLabels(owner, repo string, number int, labels []string) error {
+	_, resp, err := p.client.Issues.AddLabelsToIssue(owner, repo, number, labels)
+	if resp != nil {
+		p.recordRate(resp)
+	}
+	return err
+}
+
+// RequestReviewers asks reviewers to review a pull request. There's no
+// PullRequests.RequestReviewers on this go-github version, so this hits
+// the REST endpoint directly the same way the generated methods do.
+func (p *GitHub) RequestReviewers(owner, repo string, number int, reviewers []string) error {
+	u := fmt.Sprintf("repos/%s/%s/pulls/%d/requested_reviewers", owner, repo, number)
+	body := &struct {
+		Reviewers []string `json:"reviewers"`
+	}{Reviewers: reviewers}
+	req, err := p.client.NewRequest("POST", u, body)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Do(req, nil)
+	if resp != nil {
+		p.recordRate(resp)
+	}
+	return err
+}
+
+func (p *GitHub) AddAssignees(owner, repo string, number int, logins []string) error {
+	_, resp, err := p.client.Issues.AddAssignees(owner, repo, number, logins)
+	if resp != nil {
+		p.recordRate(resp)
+	}
+	return err
+}
+
+func (p *GitHub) RemoveAssignees(owner, repo string, number int, logins []string) error {
+	_, resp, err := p.client.Issues.RemoveAssignees(owner, repo, number, logins)
+	if resp != nil {
+		p.recordRate(resp)
+	}
+	return err
+}
+
+func (p *GitHub) AddReaction(owner, repo string, number int, reaction string) error {
+	_, resp, err := p.client.Reactions.CreateIssueReaction(owner, repo, number, reaction)
+	if resp != nil {
+		p.recordRate(resp)
+	}
+	return err
+}
+
+func (p *GitHub) recordRate(resp *github.Response) {
+	p.mu.Lock()
+	p.rate = resp.Rate
+	p.mu.Unlock()
+}
+
+// RateLimit reports the GitHub API rate-limit state observed on the most
+// recent call, so operators can watch remaining quota.
+func (p *GitHub) RateLimit() (remaining, limit int, reset time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.rate.Remaining, p.rate.Limit, p.rate.Reset.Time
+}
+
+// FromGitHubIssue normalizes a go-github Issue payload, as received from
+// the REST API or an "issues"/"issue_comment" webhook event.
+func FromGitHubIssue(issue *github.Issue) Issue {
+	return normalizeGitHubIssue(issue)
+}
+
+// FromGitHubPullRequest normalizes a go-github PullRequest payload, as
+// received from a "pull_request"/"pull_request_review" webhook event.
+func FromGitHubPullRequest(pr *github.PullRequest) Issue {
+	out := Issue{
+		IsPR: true,
+	}
+	if pr.Number != nil {
+		out.Number = *pr.Number
+	}
+	if pr.Title != nil {
+		out.Title = *pr.Title
+	}
+	if pr.Body != nil {
+		out.Body = *pr.Body
+	}
+	if pr.State != nil {
+		out.State = *pr.State
+	}
+	if pr.Merged != nil && *pr.Merged {
+		out.State = "closed"
+		out.Merged = true
+	}
+	if pr.HTMLURL != nil {
+		out.HTMLURL = *pr.HTMLURL
+	}
+	if pr.User != nil && pr.User.AvatarURL != nil {
+		out.AvatarURL = *pr.User.AvatarURL
+	}
+	if pr.CreatedAt != nil {
+		out.CreatedAt = *pr.CreatedAt
+	}
+	if pr.UpdatedAt != nil {
+		out.UpdatedAt = *pr.UpdatedAt
+	}
+	for _, l := range pr.Labels {
+		if l.Name != nil {
+			out.Labels = append(out.Labels, *l.Name)
+		}
+	}
+	for _, a := range pr.Assignees {
+		if a.Login != nil {
+			out.Assignees = append(out.Assignees, *a.Login)
+		}
+	}
+	return out
+}
+
+func normalizeGitHubIssue(issue *github.Issue) Issue {
+	out := Issue{
+		IsPR:   issue.PullRequestLinks != nil,
+		Merged: issue.PullRequestLinks != nil && issue.State != nil && *issue.State == "closed",
+	}
+	if issue.Number != nil {
+		out.Number = *issue.Number
+	}
+	if issue.Title != nil {
+		out.Title = *issue.Title
+	}
+	if issue.Body != nil {
+		out.Body = *issue.Body
+	}
+	if issue.State != nil {
+		out.State = *issue.State
+	}
+	if issue.HTMLURL != nil {
+		out.HTMLURL = *issue.HTMLURL
+	}
+	if issue.User != nil && issue.User.AvatarURL != nil {
+		out.AvatarURL = *issue.User.AvatarURL
+	}
+	if issue.CreatedAt != nil {
+		out.CreatedAt = *issue.CreatedAt
+	}
+	if issue.UpdatedAt != nil {
+		out.UpdatedAt = *issue.UpdatedAt
+	}
+	for _, l := range issue.Labels {
+		if l.Name != nil {
+			out.Labels = append(out.Labels, *l.Name)
+		}
+	}
+	for _, a := range issue.Assignees {
+		if a.Login != nil {
+			out.Assignees = append(out.Assignees, *a.Login)
+		}
+	}
+	return out
+}
@@ -0,0 +1,271 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/xanzy/go-gitlab"
+)
+
+// GitLab wraps go-gitlab as a Provider. Pull requests are represented as
+// GitLab merge requests.
+type GitLab struct {
+	client *gitlab.Client
+}
+
+// NewGitLab builds a GitLab provider against baseURL ("" for gitlab.com),
+// authenticated with token.
+func NewGitLab(baseURL, token string) (*GitLab, error) {
+	opts := []gitlab.ClientOptionFunc{}
+	if baseURL != "" {
+		opts = append(opts, gitlab.WithBaseURL(baseURL))
+	}
+	client, err := gitlab.NewClient(token, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &GitLab{client: client}, nil
+}
+
+func projectID(owner, repo string) string {
+	return owner + "/" + repo
+}
+
+func (p *GitLab) ListIssues(owner, repo string) ([]Issue, error) {
+	pid := projectID(owner, repo)
+
+	var out []Issue
+	issueOpt := &gitlab.ListProjectIssuesOptions{ListOptions: gitlab.ListOptions{PerPage: 100}}
+	for {
+		issues, resp, err := p.client.Issues.ListProjectIssues(pid, issueOpt)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range issues {
+			out = append(out, normalizeGitLabIssue(v))
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		issueOpt.Page = resp.NextPage
+	}
+
+	mrOpt := &gitlab.ListProjectMergeRequestsOptions{ListOptions: gitlab.ListOptions{PerPage: 100}}
+	for {
+		mrs, resp, err := p.client.MergeRequests.ListProjectMergeRequests(pid, mrOpt)
+		if err != nil {
+			return nil, err
+		}
+		for _, v := range mrs {
+			out = append(out, normalizeGitLabMergeRequest(v))
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		mrOpt.Page = resp.NextPage
+	}
+	return out, nil
+}
+
+func (p *GitLab) GetIssue(owner, repo string, number int) (Issue, error) {
+	pid := projectID(owner, repo)
+
+	issue, resp, err := p.client.Issues.GetIssue(pid, number)
+	if err == nil {
+		return normalizeGitLabIssue(issue), nil
+	}
+	if resp == nil || resp.StatusCode != http.StatusNotFound {
+		return Issue{}, err
+	}
+
+	mr, mrResp, mrErr := p.client.MergeRequests.GetMergeRequest(pid, number, nil)
+	if mrErr != nil {
+		if mrResp != nil && mrResp.StatusCode == http.StatusNotFound {
+			return Issue{}, &NotFoundError{Owner: owner, Repo: repo, Number: number}
+		}
+		return Issue{}, mrErr
+	}
+	return normalizeGitLabMergeRequest(mr), nil
+}
+
+// SetIssueState opens or closes number, whichever of an issue or merge
+// request it turns out to be.
+func (p *GitLab) SetIssueState(owner, repo string, number int, state string) error {
+	pid := projectID(owner, repo)
+	event := "close"
+	if state == "open" || state == "opened" || state == "reopen" {
+		event = "reopen"
+	}
+
+	if _, _, err := p.client.MergeRequests.GetMergeRequest(pid, number, nil); err == nil {
+		_, _, err := p.client.MergeRequests.UpdateMergeRequest(pid, number, &gitlab.UpdateMergeRequestOptions{StateEvent: &event})
+		return err
+	}
+	_, _, err := p.client.Issues.UpdateIssue(pid, number, &gitlab.UpdateIssueOptions{StateEvent: &event})
+	return err
+}
+
+func (p *GitLab) AddLabels(owner, repo string, number int, labels []string) error {
+	add := gitlab.LabelOptions(labels)
+	_, _, err := p.client.Issues.UpdateIssue(projectID(owner, repo), number, &gitlab.UpdateIssueOptions{AddLabels: &add})
+	return err
+}
+
+// RequestReviewers asks the given logins to review a GitLab merge
+// request. GitLab has no equivalent concept for plain issues.
+func (p *GitLab) RequestReviewers(owner, repo string, number int, reviewers []string) error {
+	ids, err := p.userIDs(reviewers)
+	if err != nil {
+		return err
+	}
+	_, _, err = p.client.MergeRequests.UpdateMergeRequest(projectID(owner, repo), number, &gitlab.UpdateMergeRequestOptions{ReviewerIDs: &ids})
+	return err
+}
+
+func (p *GitLab) AddAssignees(owner, repo string, number int, logins []string) error {
+	return p.updateAssignees(owner, repo, number, logins, true)
+}
+
+func (p *GitLab) RemoveAssignees(owner, repo string, number int, logins []string) error {
+	return p.updateAssignees(owner, repo, number, logins, false)
+}
+
+// updateAssignees adds or removes logins from number's current assignee
+// set, whichever of an issue or merge request it turns out to be — the
+// GitLab API replaces the whole assignee_ids list rather than patching
+// it, so the current set has to be read first.
+func (p *GitLab) updateAssignees(owner, repo string, number int, logins []string, add bool) error {
+	pid := projectID(owner, repo)
+	ids, err := p.userIDs(logins)
+	if err != nil {
+		return err
+	}
+
+	if mr, _, err := p.client.MergeRequests.GetMergeRequest(pid, number, nil); err == nil {
+		current := map[int]bool{}
+		for _, a := range mr.Assignees {
+			current[a.ID] = true
+		}
+		assigneeIDs := mergeIDs(current, ids, add)
+		_, _, err := p.client.MergeRequests.UpdateMergeRequest(pid, number, &gitlab.UpdateMergeRequestOptions{AssigneeIDs: &assigneeIDs})
+		return err
+	}
+
+	issue, _, err := p.client.Issues.GetIssue(pid, number)
+	if err != nil {
+		return err
+	}
+	current := map[int]bool{}
+	for _, a := range issue.Assignees {
+		current[a.ID] = true
+	}
+	assigneeIDs := mergeIDs(current, ids, add)
+	_, _, err = p.client.Issues.UpdateIssue(pid, number, &gitlab.UpdateIssueOptions{AssigneeIDs: &assigneeIDs})
+	return err
+}
+
+// userIDs resolves GitLab usernames to the numeric user IDs its
+// assignee/reviewer fields take.
+func (p *GitLab) userIDs(logins []string) ([]int, error) {
+	var ids []int
+	for _, login := range logins {
+		users, _, err := p.client.Users.ListUsers(&gitlab.ListUsersOptions{Username: gitlab.String(login)})
+		if err != nil {
+			return nil, err
+		}
+		if len(users) == 0 {
+			return nil, fmt.Errorf("gitlab: no user found for login %q", login)
+		}
+		ids = append(ids, users[0].ID)
+	}
+	return ids, nil
+}
+
+func mergeIDs(current map[int]bool, ids []int, add bool) []int {
+	for _, id := range ids {
+		if add {
+			current[id] = true
+		} else {
+			delete(current, id)
+		}
+	}
+	out := make([]int, 0, len(current))
+	for id := range current {
+		out = append(out, id)
+	}
+	return out
+}
+
+// AddReaction adds an award emoji to number, whichever of an issue or
+// merge request it turns out to be.
+func (p *GitLab) AddReaction(owner, repo string, number int, reaction string) error {
+	pid := projectID(owner, repo)
+	opt := &gitlab.CreateAwardEmojiOptions{Name: reaction}
+	if _, _, err := p.client.MergeRequests.GetMergeRequest(pid, number, nil); err == nil {
+		_, _, err := p.client.AwardEmoji.CreateMergeRequestAwardEmoji(pid, number, opt)
+		return err
+	}
+	_, _, err := p.client.AwardEmoji.CreateIssueAwardEmoji(pid, number, opt)
+	return err
+}
+
+// gitlabState maps GitLab's "opened"/"locked"/"closed"/"merged" states
+// onto the "open"/"closed" values Issue.State carries.
+func gitlabState(state string) string {
+	switch state {
+	case "opened", "locked":
+		return "open"
+	default:
+		return "closed"
+	}
+}
+
+func normalizeGitLabIssue(issue *gitlab.Issue) Issue {
+	out := Issue{
+		Number:  issue.IID,
+		Title:   issue.Title,
+		Body:    issue.Description,
+		State:   gitlabState(issue.State),
+		HTMLURL: issue.WebURL,
+		Labels:  issue.Labels,
+	}
+	if issue.Author != nil {
+		out.AvatarURL = issue.Author.AvatarURL
+	}
+	if issue.CreatedAt != nil {
+		out.CreatedAt = *issue.CreatedAt
+	}
+	if issue.UpdatedAt != nil {
+		out.UpdatedAt = *issue.UpdatedAt
+	}
+	for _, a := range issue.Assignees {
+		out.Assignees = append(out.Assignees, a.Username)
+	}
+	return out
+}
+
+func normalizeGitLabMergeRequest(mr *gitlab.MergeRequest) Issue {
+	out := Issue{
+		Number:  mr.IID,
+		Title:   mr.Title,
+		Body:    mr.Description,
+		State:   gitlabState(mr.State),
+		HTMLURL: mr.WebURL,
+		IsPR:    true,
+		Merged:  mr.State == "merged",
+		Labels:  mr.Labels,
+	}
+	if mr.Author != nil {
+		out.AvatarURL = mr.Author.AvatarURL
+	}
+	if mr.CreatedAt != nil {
+		out.CreatedAt = *mr.CreatedAt
+	}
+	if mr.UpdatedAt != nil {
+		out.UpdatedAt = *mr.UpdatedAt
+	}
+	for _, a := range mr.Assignees {
+		out.Assignees = append(out.Assignees, a.Username)
+	}
+	return out
+}
@@ -0,0 +1,73 @@
+// Package provider abstracts the Git-hosting backend (GitHub, Gitea,
+// GitLab) behind a small interface, so the rest of hashtag-bot — hashtag
+// lookups, maintenance tasks, and interactive Slack buttons alike — reads
+// and mutates issues/PRs through a normalized Issue without caring, or
+// needing to know, which backend a given repo is actually hosted on.
+package provider
+
+import (
+	"fmt"
+	"time"
+)
+
+// Issue is a normalized issue or pull request, independent of backend.
+type Issue struct {
+	Number    int
+	Title     string
+	Body      string
+	State     string // "open" or "closed"
+	HTMLURL   string
+	AvatarURL string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	IsPR      bool
+	Merged    bool
+	Labels    []string
+	Assignees []string
+}
+
+// Provider fetches issues/PRs from a Git hosting backend for one repo.
+type Provider interface {
+	// ListIssues returns every issue and pull request in owner/repo.
+	ListIssues(owner, repo string) ([]Issue, error)
+	// GetIssue fetches a single issue or pull request by number. It
+	// returns a *NotFoundError (check with IsNotFound) when the backend
+	// reports the number doesn't exist, as opposed to a transient or
+	// auth failure.
+	GetIssue(owner, repo string, number int) (Issue, error)
+
+	// SetIssueState opens or closes an issue or pull request. state is
+	// "open" or "closed".
+	SetIssueState(owner, repo string, number int, state string) error
+	// AddLabels applies labels to an issue.
+	AddLabels(owner, repo string, number int, labels []string) error
+	// RequestReviewers asks the given logins to review a pull request.
+	RequestReviewers(owner, repo string, number int, reviewers []string) error
+	// AddAssignees assigns the given logins to an issue or pull request.
+	AddAssignees(owner, repo string, number int, logins []string) error
+	// RemoveAssignees unassigns the given logins from an issue or pull
+	// request.
+	RemoveAssignees(owner, repo string, number int, logins []string) error
+	// AddReaction adds an emoji reaction (e.g. "+1") to an issue or pull
+	// request.
+	AddReaction(owner, repo string, number int, reaction string) error
+}
+
+// NotFoundError reports that owner/repo#number doesn't exist upstream.
+// Callers use it to distinguish "no such issue" from a transient error
+// (a timeout, a 5xx, an expired token), which should not be treated the
+// same way — e.g. only the former is worth negative-caching.
+type NotFoundError struct {
+	Owner, Repo string
+	Number      int
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s/%s#%d: not found", e.Owner, e.Repo, e.Number)
+}
+
+// IsNotFound reports whether err is a *NotFoundError.
+func IsNotFound(err error) bool {
+	_, ok := err.(*NotFoundError)
+	return ok
+}
@@ -0,0 +1,144 @@
+// Package render builds the Slack messages hashtag-bot posts for a
+// normalized provider.Issue: legacy colored attachments, used by the
+// webhook package's event announcements, and interactive Block Kit
+// messages with Close/Reopen/Assign-to-me/:+1: buttons, used by the
+// hashtag lookup path in main.go, that round-trip through the
+// interactions package.
+package render
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/nlopes/slack"
+
+	"github.com/babarot/hashtag-bot/provider"
+)
+
+const (
+	StateOpen     = "#67C63D"
+	StateClosed   = "#B52003"
+	StateMerged   = "#65488D"
+	StateNotFound = "#D3D3D3"
+)
+
+// Color returns the attachment color for issue's state.
+func Color(issue provider.Issue) string {
+	switch issue.State {
+	case "open":
+		return StateOpen
+	case "closed":
+		if issue.Merged {
+			return StateMerged
+		}
+		return StateClosed
+	default:
+		return StateNotFound
+	}
+}
+
+// Attachment builds the colored Slack attachment for issue.
+func Attachment(issue provider.Issue) slack.Attachment {
+	method := "Pull Requests"
+	if !issue.IsPR {
+		method = "Issues"
+	}
+	return slack.Attachment{
+		Fallback:   fmt.Sprintf("%d - %s", issue.Number, issue.Title),
+		Title:      fmt.Sprintf("<%s|%s>", issue.HTMLURL, issue.Title),
+		Text:       issue.Body,
+		MarkdownIn: []string{"title", "text", "fields", "fallback"},
+		Color:      Color(issue),
+		ThumbURL:   issue.AvatarURL,
+		Footer:     method,
+		Ts:         json.Number(fmt.Sprint(issue.CreatedAt.Unix())),
+	}
+}
+
+// AttachmentOptions wraps Attachment in the MsgOptions hashtag-bot always
+// posts with. Kept for callers that haven't moved to the Block Kit
+// messages built by Blocks, such as the webhook package's announcements.
+func AttachmentOptions(issue provider.Issue) []slack.MsgOption {
+	return []slack.MsgOption{
+		slack.MsgOptionUsername("hashtag-bot"),
+		slack.MsgOptionIconEmoji(":hash:"),
+		slack.MsgOptionAttachments(Attachment(issue)),
+	}
+}
+
+// Action IDs for the interactive buttons Blocks attaches. The
+// interactions package matches on these to decide which provider mutation
+// a button click performs.
+const (
+	ActionClose    = "hashtag_bot_close"
+	ActionReopen   = "hashtag_bot_reopen"
+	ActionAssignMe = "hashtag_bot_assign_me"
+	ActionThumbsUp = "hashtag_bot_thumbsup"
+)
+
+// PRContext carries the GitHub-specific pull request signals rendered as
+// extra context blocks: combined CI status, review decision, and
+// mergeable state. Leave a field empty to omit its block.
+type PRContext struct {
+	CIStatus       string // e.g. "success", "failure", "pending"
+	ReviewDecision string // e.g. "approved", "changes_requested", "review_required"
+	Mergeable      string // e.g. "mergeable", "conflicting", "unknown"
+}
+
+// Blocks builds the Block Kit message for issue, with interactive
+// Close/Reopen/Assign-to-me/:+1: buttons. The button values encode
+// "owner/repo/number" so the interactions handler can resolve the target
+// without re-parsing message text. pr is nil for issues that aren't pull
+// requests.
+func Blocks(owner, repo string, issue provider.Issue, pr *PRContext) []slack.Block {
+	target := fmt.Sprintf("%s/%s/%d", owner, repo, issue.Number)
+
+	method := "Issue"
+	if issue.IsPR {
+		method = "Pull Request"
+	}
+
+	section := slack.NewSectionBlock(
+		slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("*<%s|%s>*\n%s", issue.HTMLURL, issue.Title, issue.Body), false, false),
+		nil,
+		slack.NewAccessory(slack.NewImageBlockElement(issue.AvatarURL, "author avatar")),
+	)
+
+	blocks := []slack.Block{
+		section,
+		slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType,
+			fmt.Sprintf("%s %s · #%d", method, issue.State, issue.Number), false, false)),
+	}
+	if pr != nil {
+		if pr.CIStatus != "" {
+			blocks = append(blocks, slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType, "CI: *"+pr.CIStatus+"*", false, false)))
+		}
+		if pr.ReviewDecision != "" {
+			blocks = append(blocks, slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType, "Review: *"+pr.ReviewDecision+"*", false, false)))
+		}
+		if pr.Mergeable != "" {
+			blocks = append(blocks, slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType, "Mergeable: *"+pr.Mergeable+"*", false, false)))
+		}
+	}
+
+	blocks = append(blocks, slack.NewActionBlock(target,
+		slack.NewButtonBlockElement(ActionClose, target, slack.NewTextBlockObject(slack.PlainTextType, "Close", false, false)),
+		slack.NewButtonBlockElement(ActionReopen, target, slack.NewTextBlockObject(slack.PlainTextType, "Reopen", false, false)),
+		slack.NewButtonBlockElement(ActionAssignMe, target, slack.NewTextBlockObject(slack.PlainTextType, "Assign to me", false, false)),
+		slack.NewButtonBlockElement(ActionThumbsUp, target, slack.NewTextBlockObject(slack.PlainTextType, "👍", false, false)),
+	))
+	return blocks
+}
+
+// BlockOptions wraps Blocks in the MsgOptions hashtag-bot posts hashtag
+// lookups with, mirroring how AttachmentOptions wraps Attachment. The
+// message's fallback text (what notifications and plain-text clients
+// show) matches Attachment.Fallback.
+func BlockOptions(owner, repo string, issue provider.Issue, pr *PRContext) []slack.MsgOption {
+	return []slack.MsgOption{
+		slack.MsgOptionUsername("hashtag-bot"),
+		slack.MsgOptionIconEmoji(":hash:"),
+		slack.MsgOptionText(fmt.Sprintf("%d - %s", issue.Number, issue.Title), false),
+		slack.MsgOptionBlocks(Blocks(owner, repo, issue, pr)...),
+	}
+}
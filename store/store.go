@@ -0,0 +1,82 @@
+// Package store is the per-issue cache hashtag-bot looks up on a hashtag
+// hit. It's bounded by entry count (via hashicorp/golang-lru) rather than
+// the unbounded patrickmn/go-cache previously used for a full-repo sync,
+// and negatively caches misses briefly so a mistyped "#12345" in casual
+// chat doesn't repeatedly hit the provider.
+package store
+
+import (
+	"sync"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru"
+
+	"github.com/babarot/hashtag-bot/provider"
+)
+
+// NotFoundTTL is how long a miss (404) is cached.
+const NotFoundTTL = 30 * time.Second
+
+type entry struct {
+	issue     provider.Issue
+	found     bool
+	expiresAt time.Time
+}
+
+// Cache is an LRU-bounded, TTL-expiring cache of provider.Issue keyed by
+// "owner/repo/number".
+type Cache struct {
+	mu  sync.Mutex
+	lru *lru.Cache
+}
+
+// New creates a Cache holding at most size entries.
+func New(size int) (*Cache, error) {
+	l, err := lru.New(size)
+	if err != nil {
+		return nil, err
+	}
+	return &Cache{lru: l}, nil
+}
+
+// Get returns the cached issue for key and whether the entry was a
+// positive (found) hit. The second bool reports whether key was cached
+// at all (live, not expired) so callers can distinguish "never looked
+// up" from "negatively cached 404".
+func (c *Cache) Get(key string) (issue provider.Issue, found, cached bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	v, ok := c.lru.Get(key)
+	if !ok {
+		return provider.Issue{}, false, false
+	}
+	e := v.(entry)
+	if time.Now().After(e.expiresAt) {
+		c.lru.Remove(key)
+		return provider.Issue{}, false, false
+	}
+	return e.issue, e.found, true
+}
+
+// Set caches issue for key with ttl.
+func (c *Cache) Set(key string, issue provider.Issue, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru.Add(key, entry{issue: issue, found: true, expiresAt: time.Now().Add(ttl)})
+}
+
+// SetNotFound negatively caches key for NotFoundTTL.
+func (c *Cache) SetNotFound(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru.Add(key, entry{found: false, expiresAt: time.Now().Add(NotFoundTTL)})
+}
+
+// Len reports the number of entries currently cached, including ones not
+// yet evicted for expiry.
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
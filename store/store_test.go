@@ -0,0 +1,76 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/babarot/hashtag-bot/provider"
+)
+
+func TestCacheGetSet(t *testing.T) {
+	c, err := New(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, cached := c.Get("babarot/hashtag-bot/1"); cached {
+		t.Fatal("Get on an empty cache: got cached=true, want false")
+	}
+
+	issue := provider.Issue{Number: 1, Title: "first"}
+	c.Set("babarot/hashtag-bot/1", issue, time.Minute)
+
+	got, found, cached := c.Get("babarot/hashtag-bot/1")
+	if !cached || !found {
+		t.Fatalf("Get after Set: found=%v cached=%v, want true, true", found, cached)
+	}
+	if got.Title != issue.Title {
+		t.Errorf("Get after Set: got issue %+v, want %+v", got, issue)
+	}
+}
+
+func TestCacheSetNotFound(t *testing.T) {
+	c, err := New(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.SetNotFound("babarot/hashtag-bot/404")
+	_, found, cached := c.Get("babarot/hashtag-bot/404")
+	if !cached || found {
+		t.Fatalf("Get after SetNotFound: found=%v cached=%v, want false, true", found, cached)
+	}
+}
+
+func TestCacheExpiry(t *testing.T) {
+	c, err := New(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.Set("babarot/hashtag-bot/1", provider.Issue{Number: 1}, -time.Second)
+	if _, _, cached := c.Get("babarot/hashtag-bot/1"); cached {
+		t.Fatal("Get on an expired entry: got cached=true, want false")
+	}
+}
+
+func TestCacheLRUEviction(t *testing.T) {
+	c, err := New(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.Set("a", provider.Issue{Number: 1}, time.Minute)
+	c.Set("b", provider.Issue{Number: 2}, time.Minute)
+	c.Set("c", provider.Issue{Number: 3}, time.Minute)
+
+	if _, _, cached := c.Get("a"); cached {
+		t.Fatal("Get(a) after exceeding capacity: got cached=true, want false (should have been evicted)")
+	}
+	if _, _, cached := c.Get("c"); !cached {
+		t.Fatal("Get(c): got cached=false, want true (most recently added)")
+	}
+	if got := c.Len(); got != 2 {
+		t.Errorf("Len() = %d, want 2", got)
+	}
+}
@@ -0,0 +1,93 @@
+package tasks
+
+import (
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/nlopes/slack"
+
+	"github.com/babarot/hashtag-bot/provider"
+)
+
+func init() {
+	Register(requestReviewTask{})
+	Register(unassignInactiveTask{})
+}
+
+// requestReviewTask requests review from a fixed set of logins (the
+// "reviewers" option, comma-separated) on every open pull request, on
+// each run. It does not check a pull request's age or whether reviewers
+// are already requested.
+type requestReviewTask struct{}
+
+func (requestReviewTask) Name() string { return "request-review" }
+
+func (t requestReviewTask) Run(p provider.Provider, api *slack.Client, issues []provider.Issue, owner, repo string, cfg TaskConfig) error {
+	reviewers := splitCSV(cfg.Options["reviewers"])
+	if len(reviewers) == 0 {
+		return nil
+	}
+
+	for _, issue := range issues {
+		if issue.State != "open" || !issue.IsPR {
+			continue
+		}
+
+		if cfg.DryRun {
+			log.Printf("request-review: [dry-run] would request %v on %s/%s#%d", reviewers, owner, repo, issue.Number)
+			continue
+		}
+
+		if err := p.RequestReviewers(owner, repo, issue.Number, reviewers); err != nil {
+			log.Printf("request-review: %s/%s#%d: %v", owner, repo, issue.Number, err)
+		}
+	}
+	return nil
+}
+
+// unassignInactiveTask removes every assignee from an open issue/PR that
+// has had no activity in the configured number of days ("days" option,
+// default 30). Inactivity is judged from the issue's last update, not
+// from each assignee's own activity.
+type unassignInactiveTask struct{}
+
+func (unassignInactiveTask) Name() string { return "unassign-inactive" }
+
+func (t unassignInactiveTask) Run(p provider.Provider, api *slack.Client, issues []provider.Issue, owner, repo string, cfg TaskConfig) error {
+	days := 30
+	if v, ok := cfg.Options["days"]; ok {
+		if n, err := strconv.Atoi(v); err == nil {
+			days = n
+		}
+	}
+
+	for _, issue := range issues {
+		if issue.State != "open" || len(issue.Assignees) == 0 || daysSince(issue.UpdatedAt) < days {
+			continue
+		}
+
+		if cfg.DryRun {
+			log.Printf("unassign-inactive: [dry-run] would unassign %v from %s/%s#%d", issue.Assignees, owner, repo, issue.Number)
+			continue
+		}
+
+		if err := p.RemoveAssignees(owner, repo, issue.Number, issue.Assignees); err != nil {
+			log.Printf("unassign-inactive: %s/%s#%d: %v", owner, repo, issue.Number, err)
+		}
+	}
+	return nil
+}
+
+func splitCSV(s string) []string {
+	if s == "" {
+		return nil
+	}
+	var out []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
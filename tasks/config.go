@@ -0,0 +1,27 @@
+package tasks
+
+import (
+	"io/ioutil"
+
+	"gopkg.in/yaml.v2"
+)
+
+// LoadConfig reads a tasks config from path. A missing Options map is
+// normalized to an empty one so tasks can index it without a nil check.
+func LoadConfig(path string) (Config, error) {
+	var cfg Config
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := yaml.Unmarshal(b, &cfg); err != nil {
+		return cfg, err
+	}
+	for name, t := range cfg.Tasks {
+		if t.Options == nil {
+			t.Options = map[string]string{}
+			cfg.Tasks[name] = t
+		}
+	}
+	return cfg, nil
+}
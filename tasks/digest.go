@@ -0,0 +1,63 @@
+package tasks
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/nlopes/slack"
+
+	"github.com/babarot/hashtag-bot/provider"
+)
+
+func init() {
+	Register(staleDigestTask{})
+}
+
+// staleDigestTask posts a summary of open, inactive pull requests to the
+// Slack channel named in the "channel" option. The inactivity threshold
+// is read from "days" (default 14).
+type staleDigestTask struct{}
+
+func (staleDigestTask) Name() string { return "stale-digest" }
+
+func (t staleDigestTask) Run(p provider.Provider, api *slack.Client, issues []provider.Issue, owner, repo string, cfg TaskConfig) error {
+	channel := cfg.Options["channel"]
+	if channel == "" {
+		return nil
+	}
+	days := 14
+	if v, ok := cfg.Options["days"]; ok {
+		if n, err := strconv.Atoi(v); err == nil {
+			days = n
+		}
+	}
+
+	var lines []string
+	for _, issue := range issues {
+		if issue.State != "open" || !issue.IsPR || daysSince(issue.UpdatedAt) < days {
+			continue
+		}
+		lines = append(lines, fmt.Sprintf("<%s|#%d %s> — idle %dd", issue.HTMLURL, issue.Number, issue.Title, daysSince(issue.UpdatedAt)))
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+
+	text := fmt.Sprintf("Stale PRs in %s/%s:", owner, repo)
+	for _, l := range lines {
+		text += "\n" + l
+	}
+
+	if cfg.DryRun {
+		log.Printf("stale-digest: [dry-run] would post to #%s:\n%s", channel, text)
+		return nil
+	}
+
+	_, _, err := api.PostMessage(channel,
+		slack.MsgOptionText(text, false),
+		slack.MsgOptionUsername("hashtag-bot"),
+		slack.MsgOptionIconEmoji(":hash:"),
+	)
+	return err
+}
@@ -0,0 +1,46 @@
+package tasks
+
+import (
+	"log"
+	"strconv"
+
+	"github.com/nlopes/slack"
+
+	"github.com/babarot/hashtag-bot/provider"
+)
+
+func init() {
+	Register(closeStaleTask{})
+}
+
+// closeStaleTask closes open issues that have had no activity in the
+// configured number of days. The threshold defaults to 90 and is read
+// from the "days" option.
+type closeStaleTask struct{}
+
+func (closeStaleTask) Name() string { return "close-stale" }
+
+func (t closeStaleTask) Run(p provider.Provider, api *slack.Client, issues []provider.Issue, owner, repo string, cfg TaskConfig) error {
+	days := 90
+	if v, ok := cfg.Options["days"]; ok {
+		if n, err := strconv.Atoi(v); err == nil {
+			days = n
+		}
+	}
+
+	for _, issue := range issues {
+		if issue.State != "open" || issue.IsPR || daysSince(issue.UpdatedAt) < days {
+			continue
+		}
+
+		if cfg.DryRun {
+			log.Printf("close-stale: [dry-run] would close %s/%s#%d (stale %dd)", owner, repo, issue.Number, daysSince(issue.UpdatedAt))
+			continue
+		}
+
+		if err := p.SetIssueState(owner, repo, issue.Number, "closed"); err != nil {
+			log.Printf("close-stale: closing %s/%s#%d: %v", owner, repo, issue.Number, err)
+		}
+	}
+	return nil
+}
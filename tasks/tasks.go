@@ -0,0 +1,97 @@
+// Package tasks implements gopherbot-style maintenance passes over a
+// repo's issues/PRs: closing stale issues, triaging unlabeled ones,
+// requesting reviews, and posting digests. Each pass is a Task,
+// registered once at init time; main.go runs all enabled tasks together
+// on one hourly cron job against a freshly listed snapshot of each repo (tasks don't
+// share the lazy hashtag lookup cache, since they need the full repo,
+// not just the issues someone has mentioned).
+package tasks
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nlopes/slack"
+
+	"github.com/babarot/hashtag-bot/provider"
+)
+
+// Task is one maintenance pass. Implementations should be idempotent
+// since Run may be called repeatedly against the same repo state.
+type Task interface {
+	// Name identifies the task in logs and in the config file's
+	// enable/disable map.
+	Name() string
+	// Run performs the task's action against owner/repo's issues,
+	// applying cfg's thresholds. It must not panic on an empty issues
+	// slice. When cfg.DryRun is set, Run must log intended mutations
+	// instead of calling p. p is owner/repo's own provider, so mutations
+	// land on whichever backend (GitHub, Gitea, GitLab) that repo is
+	// actually configured for.
+	Run(p provider.Provider, api *slack.Client, issues []provider.Issue, owner, repo string, cfg TaskConfig) error
+}
+
+// Config controls which tasks run and their thresholds. It is loaded from
+// a YAML file by LoadConfig.
+type Config struct {
+	DryRun bool                  `yaml:"-"`
+	Tasks  map[string]TaskConfig `yaml:"tasks"`
+}
+
+// TaskConfig holds the per-task enable flag and free-form thresholds. Tasks
+// pull the settings they care about out of Options by name.
+type TaskConfig struct {
+	Enabled bool              `yaml:"enabled"`
+	Options map[string]string `yaml:"options"`
+	DryRun  bool              `yaml:"-"`
+}
+
+// Enabled reports whether name is turned on in c. Unknown task names are
+// disabled by default so a typo in the config doesn't silently run
+// everything.
+func (c Config) Enabled(name string) bool {
+	t, ok := c.Tasks[name]
+	return ok && t.Enabled
+}
+
+var registry []Task
+
+// Register adds t to the set of tasks RunAll will consider. Called from
+// each task's init().
+func Register(t Task) {
+	registry = append(registry, t)
+}
+
+// RunAll runs every registered, enabled task in cfg against owner/repo's
+// issues. In dry-run mode, tasks log intended mutations instead of
+// calling p.
+func RunAll(cfg Config, p provider.Provider, api *slack.Client, issues []provider.Issue, owner, repo string) error {
+	var errs []string
+	for _, t := range registry {
+		if !cfg.Enabled(t.Name()) {
+			continue
+		}
+		tc := cfg.Tasks[t.Name()]
+		tc.DryRun = cfg.DryRun
+		if err := t.Run(p, api, issues, owner, repo, tc); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", t.Name(), err))
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("tasks: %d task(s) failed: %s", len(errs), joinErrs(errs))
+	}
+	return nil
+}
+
+func joinErrs(errs []string) string {
+	out := errs[0]
+	for _, e := range errs[1:] {
+		out += "; " + e
+	}
+	return out
+}
+
+// daysSince returns the number of whole days between t and now.
+func daysSince(t time.Time) int {
+	return int(time.Since(t).Hours() / 24)
+}
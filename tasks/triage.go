@@ -0,0 +1,42 @@
+package tasks
+
+import (
+	"log"
+
+	"github.com/nlopes/slack"
+
+	"github.com/babarot/hashtag-bot/provider"
+)
+
+func init() {
+	Register(needsTriageTask{})
+}
+
+// needsTriageTask applies a "needs-triage" label (overridable via the
+// "label" option) to open, unlabeled issues. Pull requests are skipped.
+type needsTriageTask struct{}
+
+func (needsTriageTask) Name() string { return "needs-triage" }
+
+func (t needsTriageTask) Run(p provider.Provider, api *slack.Client, issues []provider.Issue, owner, repo string, cfg TaskConfig) error {
+	label := "needs-triage"
+	if v, ok := cfg.Options["label"]; ok && v != "" {
+		label = v
+	}
+
+	for _, issue := range issues {
+		if issue.State != "open" || issue.IsPR || len(issue.Labels) > 0 {
+			continue
+		}
+
+		if cfg.DryRun {
+			log.Printf("needs-triage: [dry-run] would label %s/%s#%d with %q", owner, repo, issue.Number, label)
+			continue
+		}
+
+		if err := p.AddLabels(owner, repo, issue.Number, []string{label}); err != nil {
+			log.Printf("needs-triage: labeling %s/%s#%d: %v", owner, repo, issue.Number, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+// Package webhook receives GitHub webhook deliveries so the shared issue
+// cache reflects new activity immediately, instead of serving an entry
+// until its TTL expires. Events listed in Server.AnnounceEvents are also
+// announced to Slack as colored attachments (render.AttachmentOptions).
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/github"
+	"github.com/nlopes/slack"
+
+	"github.com/babarot/hashtag-bot/config"
+	"github.com/babarot/hashtag-bot/provider"
+	"github.com/babarot/hashtag-bot/render"
+	"github.com/babarot/hashtag-bot/store"
+)
+
+// cacheTTL is how long a webhook-pushed issue stays cached. It's
+// generous since the webhook itself will refresh the entry on the next
+// relevant event.
+const cacheTTL = 60 * time.Minute
+
+// Server handles GitHub webhook deliveries for the repos in Config.
+type Server struct {
+	// Secret is the value configured as the webhook's shared secret;
+	// deliveries are rejected unless their X-Hub-Signature-256 matches.
+	// Leave empty only for local testing.
+	Secret string
+	Config config.Config
+	Cache  *store.Cache
+	Slack  *slack.Client
+	// AnnounceEvents lists the X-GitHub-Event names ("issues",
+	// "pull_request", "issue_comment", "pull_request_review") to post to
+	// the event's repo's Slack channels. Events not listed still update
+	// the cache, but aren't announced.
+	AnnounceEvents []string
+}
+
+// Handler returns the http.Handler to mount, typically at "/webhook".
+func (s *Server) Handler() http.Handler {
+	return http.HandlerFunc(s.handle)
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !s.validSignature(r.Header.Get("X-Hub-Signature-256"), body) {
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	event := r.Header.Get("X-GitHub-Event")
+	var err2 error
+	switch event {
+	case "issues":
+		err2 = s.handleIssues(body)
+	case "pull_request":
+		err2 = s.handlePullRequest(body)
+	case "issue_comment":
+		err2 = s.handleIssueComment(body)
+	case "pull_request_review":
+		err2 = s.handlePullRequestReview(body)
+	default:
+		log.Printf("webhook: ignoring %s event", event)
+	}
+	if err2 != nil {
+		log.Printf("webhook: handling %s event: %v", event, err2)
+		http.Error(w, err2.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) validSignature(header string, body []byte) bool {
+	if s.Secret == "" {
+		return true
+	}
+	const prefix = "sha256="
+	if !strings.HasPrefix(header, prefix) {
+		return false
+	}
+	mac := hmac.New(sha256.New, []byte(s.Secret))
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+	return hmac.Equal([]byte(strings.TrimPrefix(header, prefix)), []byte(expected))
+}
+
+func (s *Server) handleIssues(body []byte) error {
+	var ev github.IssuesEvent
+	if err := json.Unmarshal(body, &ev); err != nil {
+		return err
+	}
+	owner, repo := ownerRepo(ev.Repo)
+	issue := provider.FromGitHubIssue(ev.Issue)
+	s.cacheIssue(owner, repo, issue)
+	s.announce("issues", owner, repo, issue)
+	return nil
+}
+
+func (s *Server) handlePullRequest(body []byte) error {
+	var ev github.PullRequestEvent
+	if err := json.Unmarshal(body, &ev); err != nil {
+		return err
+	}
+	owner, repo := ownerRepo(ev.Repo)
+	issue := provider.FromGitHubPullRequest(ev.PullRequest)
+	s.cacheIssue(owner, repo, issue)
+	s.announce("pull_request", owner, repo, issue)
+	return nil
+}
+
+func (s *Server) handleIssueComment(body []byte) error {
+	var ev github.IssueCommentEvent
+	if err := json.Unmarshal(body, &ev); err != nil {
+		return err
+	}
+	owner, repo := ownerRepo(ev.Repo)
+	issue := provider.FromGitHubIssue(ev.Issue)
+	s.cacheIssue(owner, repo, issue)
+	s.announce("issue_comment", owner, repo, issue)
+	return nil
+}
+
+// pullRequestReviewEvent mirrors the "pull_request_review" webhook
+// payload. go-github's PullRequestReviewEvent doesn't exist on this
+// go-github version, so the fields this handler needs are hand-rolled
+// instead of reusing go-github's type.
+type pullRequestReviewEvent struct {
+	PullRequest *github.PullRequest `json:"pull_request"`
+	Repo        *github.Repository  `json:"repository"`
+}
+
+func (s *Server) handlePullRequestReview(body []byte) error {
+	var ev pullRequestReviewEvent
+	if err := json.Unmarshal(body, &ev); err != nil {
+		return err
+	}
+	owner, repo := ownerRepo(ev.Repo)
+	issue := provider.FromGitHubPullRequest(ev.PullRequest)
+	s.cacheIssue(owner, repo, issue)
+	s.announce("pull_request_review", owner, repo, issue)
+	return nil
+}
+
+// ownerRepo pulls the owner login and repo name out of a webhook
+// payload's Repository, matching the same manual-nil-check style
+// provider/github.go uses to normalize go-github's pointer fields.
+func ownerRepo(r *github.Repository) (owner, repo string) {
+	if r == nil {
+		return "", ""
+	}
+	if r.Owner != nil && r.Owner.Login != nil {
+		owner = *r.Owner.Login
+	}
+	if r.Name != nil {
+		repo = *r.Name
+	}
+	return owner, repo
+}
+
+// cacheIssue updates the shared cache in place so hashtag lookups see the
+// new state without waiting for the cached entry to expire.
+func (s *Server) cacheIssue(owner, repo string, issue provider.Issue) {
+	key := fmt.Sprintf("%s/%s/%d", owner, repo, issue.Number)
+	s.Cache.Set(key, issue, cacheTTL)
+}
+
+func (s *Server) announce(event, owner, repo string, issue provider.Issue) {
+	if s.Slack == nil {
+		return
+	}
+	announce := false
+	for _, e := range s.AnnounceEvents {
+		if e == event {
+			announce = true
+			break
+		}
+	}
+	if !announce {
+		return
+	}
+
+	for _, r := range s.Config.Repos {
+		if r.Owner != owner || r.Name != repo {
+			continue
+		}
+		for _, channel := range r.Channels {
+			if _, _, err := s.Slack.PostMessage(channel, render.AttachmentOptions(issue)...); err != nil {
+				log.Printf("webhook: announcing %s/%s#%d to #%s: %v", owner, repo, issue.Number, channel, err)
+			}
+		}
+	}
+}